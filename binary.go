@@ -0,0 +1,231 @@
+package gotrie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"io"
+)
+
+// ValueCodec converts stored values to and from bytes, letting callers that
+// store a single concrete type avoid gob's overhead and its requirement
+// that interface{} values be registered with gob.Register.
+type ValueCodec interface {
+	Encode(interface{}) ([]byte, error)
+	Decode([]byte) (interface{}, error)
+}
+
+// gobCodec is the default ValueCodec, used when PrefixTrie.Codec is nil.
+type gobCodec struct{}
+
+func (gobCodec) Encode(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Decode(data []byte) (interface{}, error) {
+	var v interface{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// codec returns t.Codec, falling back to gobCodec when unset.
+func (t *PrefixTrie) codec() ValueCodec {
+	if t.Codec != nil {
+		return t.Codec
+	}
+	return gobCodec{}
+}
+
+// MarshalBinary encodes the Trie so it may later be reconstructed with
+// UnmarshalBinary, without re-inserting every key.
+func (t *PrefixTrie) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := t.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary replaces the Trie's contents with the Trie previously
+// encoded by MarshalBinary.
+func (t *PrefixTrie) UnmarshalBinary(data []byte) error {
+	_, err := t.ReadFrom(bytes.NewReader(data))
+	return err
+}
+
+// WriteTo streams the encoded Trie to w, returning the number of bytes
+// written.
+func (t *PrefixTrie) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	err := writeNode(cw, t.root, t.codec())
+	return cw.n, err
+}
+
+// ReadFrom replaces the Trie's contents by decoding a Trie previously
+// written with WriteTo, returning the number of bytes read.
+func (t *PrefixTrie) ReadFrom(r io.Reader) (int64, error) {
+	cr := &countingReader{r: r}
+	root, err := readNode(cr, t.codec())
+	if err != nil {
+		return cr.n, err
+	}
+	t.root = root
+	t.bookmarks = nil
+	return cr.n, nil
+}
+
+// writeNode encodes n and, recursively, every node beneath it: a varint
+// child count, the first byte of each child edge, whether n itself is
+// valid, and, when valid, n's value encoded by codec.
+func writeNode(w *countingWriter, n *pnode, codec ValueCodec) error {
+	var childBytes []byte
+	for b := 0; b < 256; b++ {
+		if n.children[b] != nil {
+			childBytes = append(childBytes, byte(b))
+		}
+	}
+
+	if err := writeUvarint(w, uint64(len(childBytes))); err != nil {
+		return err
+	}
+	if _, err := w.Write(childBytes); err != nil {
+		return err
+	}
+
+	validByte := byte(0)
+	if n.valid {
+		validByte = 1
+	}
+	if _, err := w.Write([]byte{validByte}); err != nil {
+		return err
+	}
+
+	if n.valid {
+		blob, err := codec.Encode(n.value)
+		if err != nil {
+			return err
+		}
+		if err := writeUvarint(w, uint64(len(blob))); err != nil {
+			return err
+		}
+		if _, err := w.Write(blob); err != nil {
+			return err
+		}
+	}
+
+	for _, b := range childBytes {
+		if err := writeNode(w, n.children[b], codec); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readNode decodes a node and its descendants in the format written by
+// writeNode.
+func readNode(r *countingReader, codec ValueCodec) (*pnode, error) {
+	childCount, err := readUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+
+	childBytes := make([]byte, childCount)
+	if childCount > 0 {
+		if _, err := io.ReadFull(r, childBytes); err != nil {
+			return nil, err
+		}
+	}
+
+	var validByte [1]byte
+	if _, err := io.ReadFull(r, validByte[:]); err != nil {
+		return nil, err
+	}
+
+	n := new(pnode)
+	n.valid = validByte[0] == 1
+
+	if n.valid {
+		blobLen, err := readUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		blob := make([]byte, blobLen)
+		if blobLen > 0 {
+			if _, err := io.ReadFull(r, blob); err != nil {
+				return nil, err
+			}
+		}
+		v, err := codec.Decode(blob)
+		if err != nil {
+			return nil, err
+		}
+		n.value = v
+	}
+
+	for _, b := range childBytes {
+		c, err := readNode(r, codec)
+		if err != nil {
+			return nil, err
+		}
+		n.children[b] = c
+	}
+
+	return n, nil
+}
+
+// countingWriter wraps an io.Writer, tallying the number of bytes written.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	written, err := c.w.Write(p)
+	c.n += int64(written)
+	return written, err
+}
+
+// countingReader wraps an io.Reader, tallying the number of bytes read.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	read, err := c.r.Read(p)
+	c.n += int64(read)
+	return read, err
+}
+
+// writeUvarint writes v to w using the same variable-length encoding as
+// encoding/binary.PutUvarint.
+func writeUvarint(w io.Writer, v uint64) error {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	_, err := w.Write(buf[:n])
+	return err
+}
+
+// readUvarint reads a value written by writeUvarint.
+func readUvarint(r io.Reader) (uint64, error) {
+	var v uint64
+	var shift uint
+	var b [1]byte
+	for {
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return 0, err
+		}
+		v |= uint64(b[0]&0x7f) << shift
+		if b[0] < 0x80 {
+			return v, nil
+		}
+		shift += 7
+	}
+}
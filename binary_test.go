@@ -0,0 +1,150 @@
+package gotrie
+
+import (
+	"io"
+	"testing"
+)
+
+func TestPrefixTrieBinaryRoundTrip(t *testing.T) {
+	tr := NewPrefixTrie()
+	keys := []string{"romane", "romanus", "romulus", "rubens", "ruber", "rubicon", "rubicundus", ""}
+	for i, k := range keys {
+		tr.Insert(k, i)
+	}
+
+	data, err := tr.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr2 := NewPrefixTrie()
+	if err := tr2.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, k := range keys {
+		v, ok := tr2.Find(k)
+		if !ok || v != i {
+			t.Fatalf("Find(%q) after round trip = %v, %v; want %v, true", k, v, ok, i)
+		}
+	}
+
+	// Scan never reports a value stored at the root (empty-string key) even
+	// on the original Trie, so the round trip is not expected to either.
+	var got []string
+	for tr2.Scan() {
+		got = append(got, tr2.Text())
+	}
+	want := []string{"romane", "romanus", "romulus", "rubens", "ruber", "rubicon", "rubicundus"}
+	if len(got) != len(want) {
+		t.Fatalf("Scan after round trip = %v; want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Scan after round trip = %v; want %v", got, want)
+		}
+	}
+}
+
+func TestPrefixTrieBinaryEmpty(t *testing.T) {
+	tr := NewPrefixTrie()
+	data, err := tr.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr2 := NewPrefixTrie()
+	tr2.Insert("stale", 1) // UnmarshalBinary must replace, not merge.
+	if err := tr2.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tr2.Find("stale"); ok {
+		t.Fatal("UnmarshalBinary should discard prior contents")
+	}
+}
+
+// intCodec stores values as single-byte ints, avoiding gob and its
+// requirement that interface{} payloads be registered.
+type intCodec struct{}
+
+func (intCodec) Encode(v interface{}) ([]byte, error) {
+	return []byte{byte(v.(int))}, nil
+}
+
+func (intCodec) Decode(b []byte) (interface{}, error) {
+	return int(b[0]), nil
+}
+
+func TestPrefixTrieBinaryCustomCodec(t *testing.T) {
+	tr := NewPrefixTrie()
+	tr.Codec = intCodec{}
+	tr.Insert("a", 1)
+	tr.Insert("b", 2)
+	tr.Insert("ab", 3)
+
+	data, err := tr.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr2 := NewPrefixTrie()
+	tr2.Codec = intCodec{}
+	if err := tr2.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	for k, want := range map[string]int{"a": 1, "b": 2, "ab": 3} {
+		v, ok := tr2.Find(k)
+		if !ok || v != want {
+			t.Fatalf("Find(%q) = %v, %v; want %v, true", k, v, ok, want)
+		}
+	}
+}
+
+func TestPrefixTrieWriteToReadFromByteCount(t *testing.T) {
+	tr := NewPrefixTrie()
+	tr.Insert("a", 1)
+	tr.Insert("b", 2)
+
+	var buf writeCounter
+	n, err := tr.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(buf.n) {
+		t.Fatalf("WriteTo returned %d, but wrote %d bytes", n, buf.n)
+	}
+
+	tr2 := NewPrefixTrie()
+	read, err := tr2.ReadFrom(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read != n {
+		t.Fatalf("ReadFrom read %d bytes, want %d", read, n)
+	}
+}
+
+// writeCounter is an io.Writer/io.Reader backed by an in-memory buffer, used
+// to confirm WriteTo/ReadFrom's reported byte counts without pulling in
+// bytes.Buffer's own bookkeeping.
+type writeCounter struct {
+	data []byte
+	n    int
+	pos  int
+}
+
+func (w *writeCounter) Write(p []byte) (int, error) {
+	w.data = append(w.data, p...)
+	w.n += len(p)
+	return len(p), nil
+}
+
+func (w *writeCounter) Read(p []byte) (int, error) {
+	if w.pos >= len(w.data) && len(p) > 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, w.data[w.pos:])
+	w.pos += n
+	return n, nil
+}
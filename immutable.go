@@ -0,0 +1,303 @@
+package gotrie
+
+import "container/list"
+
+// ImmutableTrie is a persistent, copy-on-write variant of PrefixTrie. Every
+// mutation is performed inside a Txn and produces a brand new ImmutableTrie
+// rather than rewriting the one it started from, so a reader holding an
+// older *ImmutableTrie keeps seeing a consistent snapshot even while a
+// writer is busy building the next version. Because values are never
+// published until Commit returns, many readers may use their own
+// *ImmutableTrie concurrently with a single writer without locking, so long
+// as the writer publishes the new root under a mutex (or atomic.Value) once
+// it is ready:
+//
+//	var mu sync.Mutex
+//	current := gotrie.NewImmutableTrie()
+//
+//	// writer
+//	txn := current.Txn()
+//	txn.Insert("romane", 1)
+//	mu.Lock()
+//	current = txn.Commit()
+//	mu.Unlock()
+//
+//	// reader, holding its own snapshot
+//	mu.Lock()
+//	snapshot := current
+//	mu.Unlock()
+//	v, ok := snapshot.Find("romane")
+type ImmutableTrie struct {
+	root *inode
+
+	// bookmarks are used while enumerating trie contents during scanning.
+	bookmarks []*ibookmark
+}
+
+// NewImmutableTrie returns a new, empty persistent trie.
+func NewImmutableTrie() *ImmutableTrie {
+	return &ImmutableTrie{root: new(inode)}
+}
+
+// inode is a node in an immutable trie. Once published as part of some
+// ImmutableTrie, a node is never modified; mutations clone it instead.
+type inode struct {
+	children [256]*inode
+	value    interface{}
+	valid    bool
+}
+
+// Find locates the specified key and returns its respective value, along
+// with a boolean which is true when the key was found.
+func (t *ImmutableTrie) Find(key string) (interface{}, bool) {
+	n := t.root
+	for _, k := range []byte(key) {
+		c := n.children[k]
+		if c == nil {
+			return nil, false
+		}
+		n = c
+	}
+	return n.value, n.valid
+}
+
+// Txn returns a new transaction used to build the next version of the trie.
+// The transaction reads from, but never modifies, t.
+func (t *ImmutableTrie) Txn() *Txn {
+	return &Txn{root: t.root, modified: newModCache(defaultModCacheSize)}
+}
+
+// defaultModCacheSize is the default capacity of a Txn's modified cache.
+const defaultModCacheSize = 8192
+
+// Txn represents an in-flight, batched set of mutations against an
+// ImmutableTrie. A Txn is not safe for concurrent use.
+type Txn struct {
+	root     *inode
+	modified *modCache
+}
+
+// clone returns a mutable copy of n that this transaction may freely modify,
+// cloning it only the first time it is encountered. Subsequent lookups of
+// the same original node within the transaction's modified cache return the
+// previously made clone instead of allocating another one. Once a node has
+// been cloned, the clone itself is registered as already belonging to this
+// transaction, so that revisiting the clone later in the same transaction
+// (for example while descending toward a second, prefix-sharing key) hands
+// it straight back instead of cloning a clone.
+func (tx *Txn) clone(n *inode) *inode {
+	if n == nil {
+		c := new(inode)
+		tx.modified.put(c, c)
+		return c
+	}
+	if c, ok := tx.modified.get(n); ok {
+		return c
+	}
+	c := new(inode)
+	*c = *n
+	tx.modified.put(n, c)
+	tx.modified.put(c, c)
+	return c
+}
+
+// Insert stores the key-value pair, overwriting an existing value if key was
+// stored before. Only the nodes along key's path are cloned; every sibling
+// subtree is reused by pointer.
+func (tx *Txn) Insert(key string, value interface{}) {
+	n := tx.clone(tx.root)
+	tx.root = n
+
+	for _, k := range []byte(key) {
+		c := tx.clone(n.children[k])
+		n.children[k] = c
+		n = c
+	}
+
+	n.value = value
+	n.valid = true
+}
+
+// Delete removes a key-value pair, returning true when the key was found
+// prior to deletion, and false otherwise. As with Insert, only the nodes
+// along key's path are ever cloned.
+func (tx *Txn) Delete(key string) bool {
+	keyb := []byte(key)
+
+	// Check for the key's presence first, without cloning anything, so a
+	// miss costs nothing.
+	n := tx.root
+	for _, k := range keyb {
+		c := n.children[k]
+		if c == nil {
+			return false
+		}
+		n = c
+	}
+	if !n.valid {
+		return false
+	}
+
+	root := tx.clone(tx.root)
+	tx.root = root
+
+	chain := make([]*inode, 1, len(keyb)+1)
+	chain[0] = root
+	cur := root
+	for _, k := range keyb {
+		c := tx.clone(cur.children[k])
+		cur.children[k] = c
+		cur = c
+		chain = append(chain, cur)
+	}
+
+	cur.valid = false
+	cur.value = nil
+
+	// Unlink any trailing clones which are left with no value and no
+	// children, mirroring PrefixTrie.Delete's cleanup of dead chains.
+	for i := len(chain) - 1; i > 0; i-- {
+		c := chain[i]
+		if c.valid {
+			return true
+		}
+		var hasChild bool
+		for _, p := range c.children {
+			if p != nil {
+				hasChild = true
+				break
+			}
+		}
+		if hasChild {
+			return true
+		}
+		chain[i-1].children[keyb[i-1]] = nil
+	}
+
+	return true
+}
+
+// Commit finalizes the transaction and returns the new trie. The Txn must
+// not be used after calling Commit.
+func (tx *Txn) Commit() *ImmutableTrie {
+	return &ImmutableTrie{root: tx.root}
+}
+
+// modCache is a bounded LRU cache mapping an original node to the clone a
+// Txn has already made of it, so that revisiting the same node within one
+// transaction does not clone it twice.
+type modCache struct {
+	capacity int
+	ll       *list.List
+	items    map[*inode]*list.Element
+}
+
+// modEntry is the value stored in a modCache's linked list.
+type modEntry struct {
+	orig, clone *inode
+}
+
+// newModCache returns a modCache holding up to capacity entries.
+func newModCache(capacity int) *modCache {
+	return &modCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[*inode]*list.Element),
+	}
+}
+
+// get returns the clone previously made for orig, if any, and marks it most
+// recently used.
+func (c *modCache) get(orig *inode) (*inode, bool) {
+	e, ok := c.items[orig]
+	if !ok {
+		return nil, false
+	}
+	c.ll.MoveToFront(e)
+	return e.Value.(*modEntry).clone, true
+}
+
+// put records that clone is the clone made for orig, evicting the least
+// recently used entry if the cache is at capacity.
+func (c *modCache) put(orig, clone *inode) {
+	if e, ok := c.items[orig]; ok {
+		e.Value.(*modEntry).clone = clone
+		c.ll.MoveToFront(e)
+		return
+	}
+
+	e := c.ll.PushFront(&modEntry{orig: orig, clone: clone})
+	c.items[orig] = e
+
+	if c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*modEntry).orig)
+	}
+}
+
+// ibookmark is used while enumerating an immutable trie's contents during
+// scanning.
+type ibookmark struct {
+	n      *inode // n points to the bookmarked Trie node
+	prefix []byte // prefix is the collected key bytes at this node
+	k      uint16 // k is the next byte to check
+}
+
+// Scan locates the next key-value pair in the Trie. When it finds another
+// pair, it returns true; otherwise it returns false.
+//
+// This works as a continuation, or more specifically as a generator
+// function, and only does as much work as required to move the iterator to
+// the next key-value pair and return. The first time it is invoked it
+// initializes the generator. After it enumerates all key-value pairs in the
+// Trie, it may be enumerated again simply by calling this function again.
+func (t *ImmutableTrie) Scan() bool {
+	ls := len(t.bookmarks)
+	if ls == 0 {
+		t.bookmarks = []*ibookmark{{n: t.root}}
+		ls++
+	}
+
+	itop := ls - 1
+	bm := t.bookmarks[itop]
+
+outer:
+	for {
+		for ; bm.k < 256; bm.k++ {
+			child := bm.n.children[bm.k]
+			if child != nil {
+				bm = &ibookmark{
+					n:      child,
+					prefix: append(append([]byte(nil), bm.prefix...), byte(bm.k)),
+				}
+				t.bookmarks = append(t.bookmarks, bm)
+				itop++
+				if child.valid {
+					return true
+				}
+				continue outer
+			}
+		}
+
+		for bm.k == 256 {
+			if itop--; itop == -1 {
+				return false
+			}
+			bm, t.bookmarks = t.bookmarks[itop], t.bookmarks[:itop+1]
+		}
+
+		bm.k++
+	}
+}
+
+// Pair returns the key-value pair under the scanning cursor.
+func (t *ImmutableTrie) Pair() (string, interface{}) {
+	bm := t.bookmarks[len(t.bookmarks)-1] // top bookmark
+	return string(bm.prefix), bm.n.value
+}
+
+// Text returns the key of the key-value pair under the scanning cursor.
+func (t *ImmutableTrie) Text() string {
+	return string(t.bookmarks[len(t.bookmarks)-1].prefix)
+}
@@ -0,0 +1,137 @@
+package gotrie
+
+import "testing"
+
+func TestImmutableTrieFindInsert(t *testing.T) {
+	base := NewImmutableTrie()
+	txn := base.Txn()
+	keys := []string{"romane", "romanus", "romulus", "rubens", "ruber"}
+	for i, k := range keys {
+		txn.Insert(k, i)
+	}
+	v1 := txn.Commit()
+
+	for i, k := range keys {
+		v, ok := v1.Find(k)
+		if !ok || v != i {
+			t.Fatalf("Find(%q) = %v, %v; want %v, true", k, v, ok, i)
+		}
+	}
+}
+
+func TestImmutableTrieSnapshotIsolation(t *testing.T) {
+	base := NewImmutableTrie()
+
+	txn1 := base.Txn()
+	txn1.Insert("romane", 1)
+	txn1.Insert("romanus", 2)
+	txn1.Insert("romulus", 3)
+	v1 := txn1.Commit()
+
+	// base must remain untouched by txn1's mutations.
+	if _, ok := base.Find("romane"); ok {
+		t.Fatal("base snapshot should not observe txn1's inserts")
+	}
+
+	txn2 := v1.Txn()
+	txn2.Delete("romanus")
+	txn2.Insert("rubens", 4)
+	v2 := txn2.Commit()
+
+	// v1 must remain untouched by txn2's mutations: this is the central
+	// guarantee of copy-on-write persistence.
+	if v, ok := v1.Find("romanus"); !ok || v != 2 {
+		t.Fatalf("v1 should still have romanus, got %v, %v", v, ok)
+	}
+	if _, ok := v1.Find("rubens"); ok {
+		t.Fatal("v1 should not have rubens inserted by txn2")
+	}
+
+	// v2 must reflect both its own and the inherited mutations.
+	if _, ok := v2.Find("romanus"); ok {
+		t.Fatal("v2 should not have romanus, deleted by txn2")
+	}
+	if v, ok := v2.Find("romane"); !ok || v != 1 {
+		t.Fatalf("v2 should inherit romane from v1, got %v, %v", v, ok)
+	}
+	if v, ok := v2.Find("rubens"); !ok || v != 4 {
+		t.Fatalf("v2 should have rubens, got %v, %v", v, ok)
+	}
+
+	var got []string
+	for v2.Scan() {
+		got = append(got, v2.Text())
+	}
+	want := []string{"romane", "romulus", "rubens"}
+	if len(got) != len(want) {
+		t.Fatalf("v2 scan = %v; want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("v2 scan = %v; want %v", got, want)
+		}
+	}
+}
+
+func TestImmutableTrieEmptyKey(t *testing.T) {
+	base := NewImmutableTrie()
+	txn := base.Txn()
+	txn.Insert("", 1)
+	txn.Insert("a", 2)
+	v := txn.Commit()
+
+	if val, ok := v.Find(""); !ok || val != 1 {
+		t.Fatalf("Find(\"\") = %v, %v", val, ok)
+	}
+
+	// Scan never reports a value stored at the root (empty-string key),
+	// matching PrefixTrie.Scan.
+	var got []string
+	for v.Scan() {
+		got = append(got, v.Text())
+	}
+	want := []string{"a"}
+	if len(got) != len(want) || got[0] != want[0] {
+		t.Fatalf("Scan = %v; want %v", got, want)
+	}
+}
+
+func TestImmutableTrieDeleteMiss(t *testing.T) {
+	base := NewImmutableTrie()
+	txn := base.Txn()
+	txn.Insert("romane", 1)
+	if txn.Delete("nonexistent") {
+		t.Fatal("Delete of an absent key should report false")
+	}
+	if !txn.Delete("romane") {
+		t.Fatal("Delete of a present key should report true")
+	}
+	v := txn.Commit()
+	if _, ok := v.Find("romane"); ok {
+		t.Fatal("romane should be gone after delete")
+	}
+}
+
+func TestModCacheReusesClone(t *testing.T) {
+	base := NewImmutableTrie()
+	txn := base.Txn()
+
+	// "abc" and "abd" share the "ab" prefix, so the second Insert should
+	// reuse the root clone and the "a" and "ab" clones already made by the
+	// first Insert rather than cloning them again.
+	txn.Insert("abc", 1)
+	root1, a1, ab1 := txn.root, txn.root.children['a'], txn.root.children['a'].children['b']
+
+	txn.Insert("abd", 2)
+	root2, a2, ab2 := txn.root, txn.root.children['a'], txn.root.children['a'].children['b']
+
+	if root1 != root2 {
+		t.Fatal("second Insert should reuse the root clone made by the first Insert")
+	}
+	if a1 != a2 {
+		t.Fatal("second Insert should reuse the 'a' clone made by the first Insert")
+	}
+	if ab1 != ab2 {
+		t.Fatal("second Insert should reuse the 'ab' clone made by the first Insert")
+	}
+}
@@ -1,5 +1,15 @@
 package gotrie
 
+import "errors"
+
+// ErrPrefixNotFound is returned by Get when no stored key begins with the
+// requested prefix.
+var ErrPrefixNotFound = errors.New("gotrie: prefix not found")
+
+// ErrPrefixAmbiguous is returned by Get when more than one stored key begins
+// with the requested prefix.
+var ErrPrefixAmbiguous = errors.New("gotrie: prefix ambiguous")
+
 // PrefixTrie is a prefix tree, also known as a digital tree, as described by
 // https://en.wikipedia.org/wiki/Trie with a one byte radix.
 //
@@ -38,6 +48,12 @@ type PrefixTrie struct {
 
 	// bookmarks are used while enumerating trie contents during scanning.
 	bookmarks []*pbookmark
+
+	// Codec selects how values are encoded by MarshalBinary/WriteTo and
+	// decoded by UnmarshalBinary/ReadFrom. A nil Codec falls back to
+	// encoding/gob, which requires concrete value types to be registered
+	// with gob.Register beforehand.
+	Codec ValueCodec
 }
 
 // NewPrefixTrie returns a new prefix trie.
@@ -187,6 +203,226 @@ func (t *PrefixTrie) Insert(key string, value interface{}) {
 	n.valid = true
 }
 
+// WalkPrefix invokes fn for every key-value pair whose key begins with
+// prefix, visiting them in sorted order. As with Scan, a value stored at the
+// root (the empty-string key) is never reported. It stops and returns the
+// first non-nil error returned by fn.
+func (t *PrefixTrie) WalkPrefix(prefix string, fn func(key string, value interface{}) error) error {
+	n := t.root
+	pb := []byte(prefix)
+
+	for _, k := range pb {
+		c := n.children[k]
+		if c == nil {
+			// No stored key has this prefix.
+			return nil
+		}
+		n = c
+	}
+
+	return walkPrefix(n, pb, fn)
+}
+
+// walkPrefix performs an in-order traversal of the subtree rooted at n,
+// invoking fn for every valid node, with prefix holding the key bytes
+// collected so far. It never reports a value stored at the root (the
+// empty-string key), matching Scan's enumeration semantics.
+func walkPrefix(n *pnode, prefix []byte, fn func(key string, value interface{}) error) error {
+	if n.valid && len(prefix) > 0 {
+		if err := fn(string(prefix), n.value); err != nil {
+			return err
+		}
+	}
+
+	for k := 0; k < 256; k++ {
+		if c := n.children[k]; c != nil {
+			if err := walkPrefix(c, append(prefix, byte(k)), fn); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// LongestPrefix returns the longest stored key which is a prefix of key,
+// along with its value and a boolean which is true when such a key exists.
+func (t *PrefixTrie) LongestPrefix(key string) (string, interface{}, bool) {
+	n := t.root
+	var longest int
+	var found *pnode
+	if n.valid {
+		found = n
+	}
+
+	for i, k := range []byte(key) {
+		c := n.children[k]
+		if c == nil {
+			break
+		}
+		n = c
+		if n.valid {
+			found = n
+			longest = i + 1
+		}
+	}
+
+	if found == nil {
+		return "", nil, false
+	}
+	return key[:longest], found.value, true
+}
+
+// Get returns the single stored key beginning with prefix. It returns
+// ErrPrefixNotFound when no key has that prefix, and ErrPrefixAmbiguous when
+// more than one key does, making the Trie directly usable as a
+// truncated-ID index in the style of Docker's container and image IDs. A
+// value stored at the root (the empty-string key) is not counted, as in
+// WalkPrefix.
+func (t *PrefixTrie) Get(prefix string) (string, error) {
+	var key string
+	var count int
+
+	err := t.WalkPrefix(prefix, func(k string, _ interface{}) error {
+		count++
+		if count > 1 {
+			return ErrPrefixAmbiguous
+		}
+		key = k
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if count == 0 {
+		return "", ErrPrefixNotFound
+	}
+	return key, nil
+}
+
+// Seek positions the scanning cursor so that the next call to Scan returns
+// the smallest stored key greater than or equal to key, after which Scan
+// continues enumerating in sorted order as usual. Combined with WalkPrefix,
+// this allows callers to perform range queries [lo, hi) over the Trie's
+// sorted keys.
+func (t *PrefixTrie) Seek(key string) {
+	t.seek(key, false)
+}
+
+// SeekAfter is like Seek, except the next call to Scan returns the smallest
+// stored key strictly greater than key.
+func (t *PrefixTrie) SeekAfter(key string) {
+	t.seek(key, true)
+}
+
+// seek rebuilds the bookmark stack by descending from the root following
+// key's bytes, so that a subsequent Scan resumes exactly at the desired
+// boundary.
+func (t *PrefixTrie) seek(key string, exclusive bool) {
+	keyb := []byte(key)
+	bookmarks := []*pbookmark{{n: t.root}}
+	n := t.root
+
+	for i, k := range keyb {
+		c := n.children[k]
+		if c == nil {
+			// The descent fell off a missing child. No stored key shares
+			// this full prefix, so leave this bookmark's k at the byte that
+			// was just found missing: Scan's ordinary child search will
+			// skip past it and resume at the next larger byte, if any.
+			bookmarks[len(bookmarks)-1].k = uint16(k)
+			t.bookmarks = bookmarks
+			return
+		}
+
+		// Record the byte used to descend so that, should Scan later pop
+		// back to this bookmark, it resumes searching just past it.
+		bookmarks[len(bookmarks)-1].k = uint16(k)
+		bookmarks = append(bookmarks, &pbookmark{n: c, prefix: append([]byte(nil), keyb[:i+1]...)})
+		n = c
+	}
+
+	// Every byte of key matched a path in the Trie, so n is the node for
+	// key itself.
+	if !exclusive && n.valid {
+		// Drop n's own bookmark: leaving its parent positioned at the byte
+		// leading to n lets Scan's ordinary child lookup discover n afresh
+		// and report it as the match.
+		bookmarks = bookmarks[:len(bookmarks)-1]
+	}
+
+	t.bookmarks = bookmarks
+}
+
+// FuzzyFind invokes fn for every stored key within maxDist edits
+// (insertions, deletions, or substitutions) of key, a form of approximate
+// search useful for "did you mean" lookups and typo-tolerant autocomplete.
+// It stops and returns the first non-nil error returned by fn.
+func (t *PrefixTrie) FuzzyFind(key string, maxDist int, fn func(key string, value interface{}, dist int) error) error {
+	keyb := []byte(key)
+
+	row := make([]int, len(keyb)+1)
+	for i := range row {
+		row[i] = i
+	}
+
+	return fuzzyFind(t.root, nil, keyb, row, maxDist, fn)
+}
+
+// fuzzyFind performs a DFS over the trie, carrying a single row of a
+// Levenshtein distance dynamic-programming table rather than materializing
+// every candidate key: prevRow holds the distances between key's prefixes
+// and the key matched so far. A subtree is pruned as soon as no entry in its
+// row can possibly lead to a match within maxDist.
+func fuzzyFind(n *pnode, prefix []byte, key []byte, prevRow []int, maxDist int, fn func(key string, value interface{}, dist int) error) error {
+	if n.valid && prevRow[len(key)] <= maxDist {
+		if err := fn(string(prefix), n.value, prevRow[len(key)]); err != nil {
+			return err
+		}
+	}
+
+	for b := 0; b < 256; b++ {
+		c := n.children[b]
+		if c == nil {
+			continue
+		}
+
+		row := make([]int, len(key)+1)
+		row[0] = prevRow[0] + 1
+		for j := 1; j <= len(key); j++ {
+			cost := 1
+			if key[j-1] == byte(b) {
+				cost = 0
+			}
+			min := row[j-1] + 1 // deletion
+			if v := prevRow[j] + 1; v < min {
+				min = v // insertion
+			}
+			if v := prevRow[j-1] + cost; v < min {
+				min = v // substitution (or match)
+			}
+			row[j] = min
+		}
+
+		least := row[0]
+		for _, v := range row[1:] {
+			if v < least {
+				least = v
+			}
+		}
+		if least > maxDist {
+			// No descendant of this subtree can be within maxDist.
+			continue
+		}
+
+		if err := fuzzyFind(c, append(prefix, byte(b)), key, row, maxDist, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Scan locates the next key-value pair in the Trie. When it finds another pair,
 // it returns true; otherwise it returns false.
 //
@@ -0,0 +1,286 @@
+package gotrie
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPrefixTrieWalkPrefix(t *testing.T) {
+	tr := NewPrefixTrie()
+	keys := []string{"romane", "romanus", "romulus", "rubens", "ruber", "rubicon", "rubicundus"}
+	for i, k := range keys {
+		tr.Insert(k, i)
+	}
+
+	var got []string
+	var gotValues []interface{}
+	err := tr.WalkPrefix("rom", func(key string, value interface{}) error {
+		got = append(got, key)
+		gotValues = append(gotValues, value)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"romane", "romanus", "romulus"}
+	if len(got) != len(want) {
+		t.Fatalf("WalkPrefix(rom) = %v; want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] || gotValues[i] != i {
+			t.Fatalf("WalkPrefix(rom) = %v (values %v); want %v in order", got, gotValues, want)
+		}
+	}
+
+	// No stored key begins with this prefix.
+	var none []string
+	if err := tr.WalkPrefix("xyz", func(key string, _ interface{}) error {
+		none = append(none, key)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if none != nil {
+		t.Fatalf("WalkPrefix(xyz) = %v; want none", none)
+	}
+}
+
+func TestPrefixTrieWalkPrefixSkipsRootKey(t *testing.T) {
+	tr := NewPrefixTrie()
+	tr.Insert("", 1)
+	tr.Insert("a", 2)
+
+	// WalkPrefix must never report a value stored at the root (the
+	// empty-string key), matching Scan's semantics.
+	var got []string
+	if err := tr.WalkPrefix("", func(key string, _ interface{}) error {
+		got = append(got, key)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"a"}
+	if len(got) != len(want) || got[0] != want[0] {
+		t.Fatalf("WalkPrefix(\"\") = %v; want %v", got, want)
+	}
+}
+
+func TestPrefixTrieLongestPrefix(t *testing.T) {
+	tr := NewPrefixTrie()
+	tr.Insert("ro", 1)
+	tr.Insert("rom", 2)
+	tr.Insert("roman", 3)
+
+	key, value, ok := tr.LongestPrefix("romanus")
+	if !ok || key != "roman" || value != 3 {
+		t.Fatalf("LongestPrefix(romanus) = %q, %v, %v; want roman, 3, true", key, value, ok)
+	}
+
+	if _, _, ok := tr.LongestPrefix("xyz"); ok {
+		t.Fatal("LongestPrefix(xyz) should miss; no stored key is a prefix of it")
+	}
+}
+
+func TestPrefixTrieLongestPrefixRootValid(t *testing.T) {
+	tr := NewPrefixTrie()
+	tr.Insert("", 0)
+
+	// The root represents the stored empty-string key, which is a prefix of
+	// every key, so it is the fallback match even when no other node along
+	// the path is valid.
+	key, value, ok := tr.LongestPrefix("zzz")
+	if !ok || key != "" || value != 0 {
+		t.Fatalf("LongestPrefix(zzz) = %q, %v, %v; want \"\", 0, true", key, value, ok)
+	}
+}
+
+func TestPrefixTrieGet(t *testing.T) {
+	tr := NewPrefixTrie()
+	tr.Insert("abcdef", 1)
+	tr.Insert("abcxyz", 2)
+
+	if key, err := tr.Get("abcdef"); err != nil || key != "abcdef" {
+		t.Fatalf("Get(abcdef) = %q, %v; want abcdef, nil", key, err)
+	}
+
+	if _, err := tr.Get("abc"); !errors.Is(err, ErrPrefixAmbiguous) {
+		t.Fatalf("Get(abc) = %v; want ErrPrefixAmbiguous", err)
+	}
+
+	if _, err := tr.Get("xyz"); !errors.Is(err, ErrPrefixNotFound) {
+		t.Fatalf("Get(xyz) = %v; want ErrPrefixNotFound", err)
+	}
+}
+
+func TestPrefixTrieGetEmptyPrefixExcludesRootKey(t *testing.T) {
+	tr := NewPrefixTrie()
+	tr.Insert("", 1)
+	tr.Insert("a", 2)
+
+	// The root's own value is never reported, so "" unambiguously resolves
+	// to the single remaining key, matching WalkPrefix and Scan.
+	key, err := tr.Get("")
+	if err != nil || key != "a" {
+		t.Fatalf("Get(\"\") = %q, %v; want a, nil", key, err)
+	}
+}
+
+func scanAll(t *testing.T, tr *PrefixTrie) []string {
+	t.Helper()
+	var got []string
+	for tr.Scan() {
+		got = append(got, tr.Text())
+	}
+	return got
+}
+
+func assertStrings(t *testing.T, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	}
+}
+
+func TestPrefixTrieSeekInclusiveVsExclusive(t *testing.T) {
+	keys := []string{"romane", "romanus", "romulus", "rubens"}
+
+	tr := NewPrefixTrie()
+	for i, k := range keys {
+		tr.Insert(k, i)
+	}
+	tr.Seek("romanus")
+	assertStrings(t, scanAll(t, tr), []string{"romanus", "romulus", "rubens"})
+
+	tr2 := NewPrefixTrie()
+	for i, k := range keys {
+		tr2.Insert(k, i)
+	}
+	tr2.SeekAfter("romanus")
+	assertStrings(t, scanAll(t, tr2), []string{"romulus", "rubens"})
+}
+
+func TestPrefixTrieSeekBelowAllKeys(t *testing.T) {
+	keys := []string{"romane", "romanus", "romulus"}
+	tr := NewPrefixTrie()
+	for i, k := range keys {
+		tr.Insert(k, i)
+	}
+
+	tr.Seek("a")
+	assertStrings(t, scanAll(t, tr), keys)
+}
+
+func TestPrefixTrieSeekAboveAllKeys(t *testing.T) {
+	keys := []string{"romane", "romanus", "romulus"}
+	tr := NewPrefixTrie()
+	for i, k := range keys {
+		tr.Insert(k, i)
+	}
+
+	tr.Seek("zzz")
+	if tr.Scan() {
+		t.Fatalf("Scan after Seek past every key should return false, got key %q", tr.Text())
+	}
+}
+
+func TestPrefixTrieSeekInteriorMiss(t *testing.T) {
+	keys := []string{"apple", "applesauce", "banana"}
+	tr := NewPrefixTrie()
+	for i, k := range keys {
+		tr.Insert(k, i)
+	}
+
+	// "applz" shares the "appl" prefix with both apple keys but diverges at
+	// the byte after, which is missing from the trie entirely, so the seek
+	// must land on the next key in sorted order: "banana".
+	tr.Seek("applz")
+	assertStrings(t, scanAll(t, tr), []string{"banana"})
+}
+
+func TestPrefixTrieSeekInteriorNonValidNode(t *testing.T) {
+	keys := []string{"romane", "romanus", "romulus"}
+	tr := NewPrefixTrie()
+	for i, k := range keys {
+		tr.Insert(k, i)
+	}
+
+	// "rom" is an intermediate node on the path to every stored key, but is
+	// not itself a stored key, so seeking to it must resume at "romane".
+	tr.Seek("rom")
+	assertStrings(t, scanAll(t, tr), keys)
+}
+
+func TestPrefixTrieFuzzyFind(t *testing.T) {
+	tr := NewPrefixTrie()
+	keys := []string{"romane", "romanus", "romulus", "rubens"}
+	for i, k := range keys {
+		tr.Insert(k, i)
+	}
+
+	collect := func(maxDist int) map[string]int {
+		out := make(map[string]int)
+		err := tr.FuzzyFind("romanus", maxDist, func(key string, _ interface{}, dist int) error {
+			out[key] = dist
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	// Exact match: dist 0.
+	got := collect(0)
+	want := map[string]int{"romanus": 0}
+	if len(got) != len(want) || got["romanus"] != 0 {
+		t.Fatalf("FuzzyFind(romanus, 0) = %v; want %v", got, want)
+	}
+
+	// Within maxDist 2: "romane" (substitute "u"->"e", delete "s") and
+	// "romulus" (substitute "a"->"u", substitute "n"->"l") are both two edits
+	// away.
+	got = collect(2)
+	if d, ok := got["romane"]; !ok || d != 2 {
+		t.Fatalf("FuzzyFind(romanus, 2)[romane] = %v, %v; want 2, true", d, ok)
+	}
+	if d, ok := got["romulus"]; !ok || d != 2 {
+		t.Fatalf("FuzzyFind(romanus, 2)[romulus] = %v, %v; want 2, true", d, ok)
+	}
+	// "rubens" is four edits away, so it must be pruned at maxDist 2.
+	if _, ok := got["rubens"]; ok {
+		t.Fatalf("FuzzyFind(romanus, 2) should not report rubens; got dist %v", got["rubens"])
+	}
+
+	// Insertion and deletion, not just substitution, must be counted.
+	tr2 := NewPrefixTrie()
+	tr2.Insert("cat", 1)
+	tr2.Insert("cats", 2) // one insertion away from "cat"
+	tr2.Insert("at", 3)   // one deletion away from "cat"
+	tr2.Insert("cut", 4)  // one substitution away from "cat"
+	tr2.Insert("dog", 5)  // far away, must be pruned
+
+	got2 := make(map[string]int)
+	if err := tr2.FuzzyFind("cat", 1, func(key string, _ interface{}, dist int) error {
+		got2[key] = dist
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	want2 := map[string]int{"cat": 0, "cats": 1, "at": 1, "cut": 1}
+	if len(got2) != len(want2) {
+		t.Fatalf("FuzzyFind(cat, 1) = %v; want %v", got2, want2)
+	}
+	for k, d := range want2 {
+		if got2[k] != d {
+			t.Fatalf("FuzzyFind(cat, 1)[%s] = %v; want %v", k, got2[k], d)
+		}
+	}
+	if _, ok := got2["dog"]; ok {
+		t.Fatal("FuzzyFind(cat, 1) should not report dog; it is outside maxDist")
+	}
+}
@@ -0,0 +1,366 @@
+package gotrie
+
+import (
+	"bytes"
+	"sort"
+)
+
+// RadixTrie is a compressed prefix tree, also known as a PATRICIA trie or
+// radix tree, as described by
+// https://en.wikipedia.org/wiki/Radix_tree. Unlike PrefixTrie, which
+// allocates one node per byte of every stored key, RadixTrie stores a
+// byte-slice prefix on each node and only branches where two keys diverge,
+// making it considerably more memory efficient for sparse key sets.
+//
+//	t := gotrie.NewRadixTrie()
+//	t.Insert("romane", 1)
+//	t.Insert("romanus", 2)
+//	t.Insert("romulus", 3)
+//
+//	for t.Scan() {
+//	    fmt.Println(t.Text())
+//	}
+type RadixTrie struct {
+	// root represents the root node of the tree, and is associated with the
+	// empty string.
+	root *rnode
+
+	// bookmarks are used while enumerating trie contents during scanning.
+	bookmarks []*rbookmark
+}
+
+// NewRadixTrie returns a new radix trie.
+func NewRadixTrie() *RadixTrie {
+	return &RadixTrie{root: new(rnode)}
+}
+
+// radixDenseThreshold is the number of children a node may hold in its sparse
+// representation before it is promoted to a dense 256-entry array.
+const radixDenseThreshold = 8
+
+// rchildren is the set of children of a rnode. It starts out as a pair of
+// sorted slices keyed by the child's first byte, which is cheap for nodes
+// with few children, and is promoted to a dense [256]*rnode array once the
+// number of children exceeds radixDenseThreshold.
+type rchildren struct {
+	bytes []byte
+	nodes []*rnode
+	dense *[256]*rnode
+}
+
+// get returns the child keyed by b, or nil if there is none.
+func (c *rchildren) get(b byte) *rnode {
+	if c.dense != nil {
+		return c.dense[b]
+	}
+	i := sort.Search(len(c.bytes), func(i int) bool { return c.bytes[i] >= b })
+	if i < len(c.bytes) && c.bytes[i] == b {
+		return c.nodes[i]
+	}
+	return nil
+}
+
+// set stores n as the child keyed by b, replacing any existing child.
+func (c *rchildren) set(b byte, n *rnode) {
+	if c.dense != nil {
+		c.dense[b] = n
+		return
+	}
+
+	i := sort.Search(len(c.bytes), func(i int) bool { return c.bytes[i] >= b })
+	if i < len(c.bytes) && c.bytes[i] == b {
+		c.nodes[i] = n
+		return
+	}
+
+	c.bytes = append(c.bytes, 0)
+	copy(c.bytes[i+1:], c.bytes[i:])
+	c.bytes[i] = b
+
+	c.nodes = append(c.nodes, nil)
+	copy(c.nodes[i+1:], c.nodes[i:])
+	c.nodes[i] = n
+
+	if len(c.bytes) > radixDenseThreshold {
+		c.promote()
+	}
+}
+
+// remove deletes the child keyed by b, if any.
+func (c *rchildren) remove(b byte) {
+	if c.dense != nil {
+		c.dense[b] = nil
+		return
+	}
+
+	i := sort.Search(len(c.bytes), func(i int) bool { return c.bytes[i] >= b })
+	if i == len(c.bytes) || c.bytes[i] != b {
+		return
+	}
+	c.bytes = append(c.bytes[:i], c.bytes[i+1:]...)
+	c.nodes = append(c.nodes[:i], c.nodes[i+1:]...)
+}
+
+// count returns the number of children.
+func (c *rchildren) count() int {
+	if c.dense != nil {
+		var n int
+		for _, p := range c.dense {
+			if p != nil {
+				n++
+			}
+		}
+		return n
+	}
+	return len(c.bytes)
+}
+
+// each invokes fn for every child in ascending byte order.
+func (c *rchildren) each(fn func(b byte, n *rnode)) {
+	if c.dense != nil {
+		for i, p := range c.dense {
+			if p != nil {
+				fn(byte(i), p)
+			}
+		}
+		return
+	}
+	for i, b := range c.bytes {
+		fn(b, c.nodes[i])
+	}
+}
+
+// next returns the child with the smallest key strictly greater than after,
+// where after of -1 matches every key. The ok result is false when no such
+// child exists.
+func (c *rchildren) next(after int) (b int, n *rnode, ok bool) {
+	if c.dense != nil {
+		for i := after + 1; i < 256; i++ {
+			if c.dense[i] != nil {
+				return i, c.dense[i], true
+			}
+		}
+		return 0, nil, false
+	}
+	i := sort.Search(len(c.bytes), func(i int) bool { return int(c.bytes[i]) > after })
+	if i == len(c.bytes) {
+		return 0, nil, false
+	}
+	return int(c.bytes[i]), c.nodes[i], true
+}
+
+// promote converts the sparse representation to a dense 256-entry array.
+func (c *rchildren) promote() {
+	var dense [256]*rnode
+	for i, b := range c.bytes {
+		dense[b] = c.nodes[i]
+	}
+	c.dense = &dense
+	c.bytes = nil
+	c.nodes = nil
+}
+
+// rnode is a node in a radix trie. prefix holds the bytes of the edge leading
+// into this node from its parent; the root node's prefix is always empty.
+type rnode struct {
+	prefix   []byte
+	children rchildren
+	value    interface{}
+	valid    bool
+}
+
+// commonPrefixLen returns the length of the longest common prefix of a and b.
+func commonPrefixLen(a, b []byte) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	var i int
+	for i < n && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+// Find locates the specified key and returns its respective value, along
+// with a boolean which is true when the key was found.
+func (t *RadixTrie) Find(key string) (interface{}, bool) {
+	n := t.root
+	k := []byte(key)
+
+	for len(k) > 0 {
+		c := n.children.get(k[0])
+		if c == nil || !bytes.HasPrefix(k, c.prefix) {
+			return nil, false
+		}
+		k = k[len(c.prefix):]
+		n = c
+	}
+
+	return n.value, n.valid
+}
+
+// Insert stores the key-value pair in the Trie, overwriting an existing
+// value if key was stored before.
+func (t *RadixTrie) Insert(key string, value interface{}) {
+	n := t.root
+	k := []byte(key)
+
+	for {
+		if len(k) == 0 {
+			n.value = value
+			n.valid = true
+			return
+		}
+
+		c := n.children.get(k[0])
+		if c == nil {
+			n.children.set(k[0], &rnode{prefix: append([]byte(nil), k...), value: value, valid: true})
+			return
+		}
+
+		cp := commonPrefixLen(k, c.prefix)
+		if cp == len(c.prefix) {
+			// The edge is entirely consumed; keep descending.
+			k = k[cp:]
+			n = c
+			continue
+		}
+
+		// The new key diverges partway through the edge, so split it:
+		// create an intermediate node holding the common prefix, and attach
+		// the existing node and a new leaf as its children.
+		mid := &rnode{prefix: append([]byte(nil), c.prefix[:cp]...)}
+		c.prefix = append([]byte(nil), c.prefix[cp:]...)
+		mid.children.set(c.prefix[0], c)
+		n.children.set(k[0], mid)
+
+		k = k[cp:]
+		if len(k) == 0 {
+			mid.value = value
+			mid.valid = true
+		} else {
+			mid.children.set(k[0], &rnode{prefix: append([]byte(nil), k...), value: value, valid: true})
+		}
+		return
+	}
+}
+
+// Delete removes a key-value pair from the Trie, returning true when the key
+// was found in the Trie prior to deletion, and false otherwise.
+func (t *RadixTrie) Delete(key string) bool {
+	n := t.root
+	k := []byte(key)
+	path := []*rnode{n}
+
+	for len(k) > 0 {
+		c := n.children.get(k[0])
+		if c == nil || !bytes.HasPrefix(k, c.prefix) {
+			return false
+		}
+		k = k[len(c.prefix):]
+		n = c
+		path = append(path, n)
+	}
+
+	if !n.valid {
+		return false
+	}
+	n.valid = false
+	n.value = nil
+
+	// Walk back toward the root, removing nodes that are no longer needed
+	// and merging any node left with exactly one child into that child, so
+	// the invariant that every node either is valid or branches is restored.
+	for i := len(path) - 1; i > 0; i-- {
+		cur := path[i]
+		if cur.valid {
+			return true
+		}
+
+		switch cur.children.count() {
+		case 0:
+			path[i-1].children.remove(cur.prefix[0])
+		case 1:
+			var child *rnode
+			cur.children.each(func(_ byte, cn *rnode) { child = cn })
+			cur.prefix = append(cur.prefix, child.prefix...)
+			cur.value = child.value
+			cur.valid = child.valid
+			cur.children = child.children
+			return true
+		default:
+			return true
+		}
+	}
+
+	return true
+}
+
+// rbookmark is used while enumerating a radix trie's contents during
+// scanning.
+type rbookmark struct {
+	n      *rnode // n points to the bookmarked Trie node
+	prefix []byte // prefix is the collected key bytes at this node
+	k      int    // k is the first byte of the last child visited, or -1
+}
+
+// Scan locates the next key-value pair in the Trie. When it finds another
+// pair, it returns true; otherwise it returns false.
+//
+// This works as a continuation, or more specifically as a generator
+// function, and only does as much work as required to move the iterator to
+// the next key-value pair and return. The first time it is invoked it
+// initializes the generator. After it enumerates all key-value pairs in the
+// Trie, it may be enumerated again simply by calling this function again.
+func (t *RadixTrie) Scan() bool {
+	ls := len(t.bookmarks)
+	if ls == 0 {
+		t.bookmarks = []*rbookmark{{n: t.root, k: -1}}
+		ls++
+	}
+
+	itop := ls - 1
+	bm := t.bookmarks[itop]
+
+outer:
+	for {
+		for {
+			b, child, ok := bm.n.children.next(bm.k)
+			if !ok {
+				break
+			}
+			bm.k = b
+
+			bm = &rbookmark{
+				n:      child,
+				prefix: append(append([]byte(nil), bm.prefix...), child.prefix...),
+				k:      -1,
+			}
+			t.bookmarks = append(t.bookmarks, bm)
+			itop++
+			if child.valid {
+				return true
+			}
+			continue outer
+		}
+
+		// Current bookmarked node has no additional children, so pop the
+		// bookmark stack and resume searching from the parent.
+		if itop--; itop == -1 {
+			return false
+		}
+		bm, t.bookmarks = t.bookmarks[itop], t.bookmarks[:itop+1]
+	}
+}
+
+// Pair returns the key-value pair under the scanning cursor.
+func (t *RadixTrie) Pair() (string, interface{}) {
+	bm := t.bookmarks[len(t.bookmarks)-1] // top bookmark
+	return string(bm.prefix), bm.n.value
+}
+
+// Text returns the key of the key-value pair under the scanning cursor.
+func (t *RadixTrie) Text() string {
+	return string(t.bookmarks[len(t.bookmarks)-1].prefix)
+}
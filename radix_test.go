@@ -0,0 +1,263 @@
+package gotrie
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRadixTrieFindInsert(t *testing.T) {
+	tr := NewRadixTrie()
+	keys := []string{"romane", "romanus", "romulus", "rubens", "ruber", "rubicon", "rubicundus"}
+	for i, k := range keys {
+		tr.Insert(k, i)
+	}
+	for i, k := range keys {
+		v, ok := tr.Find(k)
+		if !ok || v != i {
+			t.Fatalf("Find(%q) = %v, %v; want %v, true", k, v, ok, i)
+		}
+	}
+	if _, ok := tr.Find("rom"); ok {
+		t.Fatal("Find(rom) should not match an unstored intermediate prefix")
+	}
+	if _, ok := tr.Find("romanusx"); ok {
+		t.Fatal("Find(romanusx) should not match past the end of a stored key")
+	}
+}
+
+func TestRadixTrieEdgeSplit(t *testing.T) {
+	tr := NewRadixTrie()
+	tr.Insert("romane", 1)
+
+	// Inserting "romanus" shares the "roman" prefix with "romane" but
+	// diverges at the final byte, which must split the "romane" edge into
+	// "roman" and "e".
+	tr.Insert("romanus", 2)
+
+	if v, ok := tr.Find("romane"); !ok || v != 1 {
+		t.Fatalf("romane = %v, %v", v, ok)
+	}
+	if v, ok := tr.Find("romanus"); !ok || v != 2 {
+		t.Fatalf("romanus = %v, %v", v, ok)
+	}
+
+	// Overwriting an existing key must not disturb its sibling.
+	tr.Insert("romane", 3)
+	if v, ok := tr.Find("romane"); !ok || v != 3 {
+		t.Fatalf("romane after overwrite = %v, %v", v, ok)
+	}
+	if v, ok := tr.Find("romanus"); !ok || v != 2 {
+		t.Fatalf("romanus after sibling overwrite = %v, %v", v, ok)
+	}
+}
+
+func TestScanRootKeySemanticsAgree(t *testing.T) {
+	keys := []string{"romane", "romanus", "romulus", ""}
+
+	pt := NewPrefixTrie()
+	rt := NewRadixTrie()
+	itxn := NewImmutableTrie().Txn()
+	for i, k := range keys {
+		pt.Insert(k, i)
+		rt.Insert(k, i)
+		itxn.Insert(k, i)
+	}
+	it := itxn.Commit()
+
+	var pgot, rgot, igot []string
+	for pt.Scan() {
+		pgot = append(pgot, pt.Text())
+	}
+	for rt.Scan() {
+		rgot = append(rgot, rt.Text())
+	}
+	for it.Scan() {
+		igot = append(igot, it.Text())
+	}
+
+	if len(pgot) != len(rgot) || len(pgot) != len(igot) {
+		t.Fatalf("Scan result counts disagree: PrefixTrie %v, RadixTrie %v, ImmutableTrie %v", pgot, rgot, igot)
+	}
+	for i := range pgot {
+		if pgot[i] != rgot[i] || pgot[i] != igot[i] {
+			t.Fatalf("Scan results disagree at %d: PrefixTrie %v, RadixTrie %v, ImmutableTrie %v", i, pgot, rgot, igot)
+		}
+	}
+}
+
+func TestRadixTrieEmptyKey(t *testing.T) {
+	tr := NewRadixTrie()
+	tr.Insert("", 1)
+	tr.Insert("a", 2)
+
+	if v, ok := tr.Find(""); !ok || v != 1 {
+		t.Fatalf("Find(\"\") = %v, %v", v, ok)
+	}
+
+	// Scan never reports a value stored at the root (empty-string key),
+	// matching PrefixTrie.Scan.
+	var got []string
+	for tr.Scan() {
+		got = append(got, tr.Text())
+	}
+	want := []string{"a"}
+	if len(got) != len(want) || got[0] != want[0] {
+		t.Fatalf("Scan = %v; want %v", got, want)
+	}
+
+	if !tr.Delete("") {
+		t.Fatal("Delete(\"\") should report the key was found")
+	}
+	if _, ok := tr.Find(""); ok {
+		t.Fatal("Find(\"\") should miss after delete")
+	}
+	if v, ok := tr.Find("a"); !ok || v != 2 {
+		t.Fatalf("Find(a) after deleting root key = %v, %v", v, ok)
+	}
+}
+
+func TestRadixTrieDeleteMerge(t *testing.T) {
+	tr := NewRadixTrie()
+	tr.Insert("romane", 1)
+	tr.Insert("romanus", 2)
+	tr.Insert("romulus", 3)
+
+	// "rom" now branches into "an{e,us}" and "ulus"; deleting "romane"
+	// should merge the "an" node with its remaining "us" child into a single
+	// "anus" edge rather than leaving a dangling single-child node.
+	if !tr.Delete("romane") {
+		t.Fatal("Delete(romane) should report found")
+	}
+	if v, ok := tr.Find("romanus"); !ok || v != 2 {
+		t.Fatalf("romanus after merge = %v, %v", v, ok)
+	}
+	if _, ok := tr.Find("romane"); ok {
+		t.Fatal("romane should be gone")
+	}
+
+	if !tr.Delete("romanus") {
+		t.Fatal("Delete(romanus) should report found")
+	}
+	if v, ok := tr.Find("romulus"); !ok || v != 3 {
+		t.Fatalf("romulus after second merge = %v, %v", v, ok)
+	}
+
+	if !tr.Delete("romulus") {
+		t.Fatal("Delete(romulus) should report found")
+	}
+	if tr.root.children.count() != 0 {
+		t.Fatalf("root should have no children left, has %d", tr.root.children.count())
+	}
+
+	if tr.Delete("romulus") {
+		t.Fatal("deleting an already-removed key should report not found")
+	}
+}
+
+func TestRadixTrieDensePromotion(t *testing.T) {
+	tr := NewRadixTrie()
+	// One more than radixDenseThreshold forces the root's children from
+	// the sparse representation into the dense [256]*rnode array.
+	n := radixDenseThreshold + 1
+	for i := 0; i < n; i++ {
+		tr.Insert(string(rune('a'+i)), i)
+	}
+	if tr.root.children.dense == nil {
+		t.Fatalf("expected root children to be promoted to dense after %d inserts", n)
+	}
+	for i := 0; i < n; i++ {
+		k := string(rune('a' + i))
+		if v, ok := tr.Find(k); !ok || v != i {
+			t.Fatalf("Find(%q) = %v, %v; want %v, true", k, v, ok, i)
+		}
+	}
+	var got []string
+	for tr.Scan() {
+		got = append(got, tr.Text())
+	}
+	if len(got) != n {
+		t.Fatalf("Scan after promotion returned %d keys, want %d: %v", len(got), n, got)
+	}
+	for i, k := range got {
+		if k != string(rune('a'+i)) {
+			t.Fatalf("Scan[%d] = %q; want sorted order", i, k)
+		}
+	}
+}
+
+func TestRadixTrieScanSortedOrder(t *testing.T) {
+	tr := NewRadixTrie()
+	keys := []string{"romulus", "romane", "rubicundus", "romanus", "rubens", "ruber", "rubicon"}
+	for i, k := range keys {
+		tr.Insert(k, i)
+	}
+	want := []string{"romane", "romanus", "romulus", "rubens", "ruber", "rubicon", "rubicundus"}
+	var got []string
+	for tr.Scan() {
+		got = append(got, tr.Text())
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v want %v", got, want)
+		}
+	}
+}
+
+// benchmarkKeys returns a realistic-looking set of sparse, overlapping keys
+// such as one would find in a routing table or a file path index.
+func benchmarkKeys(n int) []string {
+	keys := make([]string, n)
+	for i := 0; i < n; i++ {
+		keys[i] = fmt.Sprintf("/var/lib/service-%04d/data/shard-%03d", i%500, i%37)
+	}
+	return keys
+}
+
+func BenchmarkPrefixTrieInsert(b *testing.B) {
+	keys := benchmarkKeys(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tr := NewPrefixTrie()
+		for _, k := range keys {
+			tr.Insert(k, struct{}{})
+		}
+	}
+}
+
+func BenchmarkRadixTrieInsert(b *testing.B) {
+	keys := benchmarkKeys(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tr := NewRadixTrie()
+		for _, k := range keys {
+			tr.Insert(k, struct{}{})
+		}
+	}
+}
+
+func BenchmarkPrefixTrieFind(b *testing.B) {
+	keys := benchmarkKeys(10000)
+	tr := NewPrefixTrie()
+	for _, k := range keys {
+		tr.Insert(k, struct{}{})
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tr.Find(keys[i%len(keys)])
+	}
+}
+
+func BenchmarkRadixTrieFind(b *testing.B) {
+	keys := benchmarkKeys(10000)
+	tr := NewRadixTrie()
+	for _, k := range keys {
+		tr.Insert(k, struct{}{})
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tr.Find(keys[i%len(keys)])
+	}
+}